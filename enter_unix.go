@@ -0,0 +1,53 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// shellCommandArgv builds the argv/env used to hand control to the user's
+// interactive shell.
+func shellCommandArgv(targetPath string) ([]string, []string) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	// argv[0] is the command name (by convention)
+	// "-i" forces the shell to be interactive (load history/rc files)
+	// Note: Some shells behave better if argv[0] starts with "-" (login shell),
+	// but "-i" is the standard way to just "start a new interactive session".
+	return []string{shell, "-i"}, os.Environ()
+}
+
+// execReplace hands control to argv[0] in place of the current process; on
+// success it never returns.
+func execReplace(argv []string, env []string) error {
+	return syscall.Exec(argv[0], argv, env)
+}
+
+func copyToClipboard(text string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("pbcopy")
+	case "linux":
+		if _, err := exec.LookPath("xclip"); err == nil {
+			cmd = exec.Command("xclip", "-selection", "clipboard")
+		} else if _, err := exec.LookPath("wl-copy"); err == nil {
+			cmd = exec.Command("wl-copy")
+		} else {
+			return fmt.Errorf("no clipboard tool found")
+		}
+	default:
+		return fmt.Errorf("unsupported OS")
+	}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
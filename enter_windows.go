@@ -0,0 +1,57 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// shellCommandArgv builds the argv/env used to hand control to the user's
+// interactive shell: pwsh.exe if on PATH, otherwise powershell.exe,
+// otherwise whatever COMSPEC points to.
+func shellCommandArgv(targetPath string) ([]string, []string) {
+	shellPath, args := detectWindowsShell()
+	return append([]string{shellPath}, args...), os.Environ()
+}
+
+func detectWindowsShell() (string, []string) {
+	if p, err := exec.LookPath("pwsh.exe"); err == nil {
+		return p, []string{"-NoLogo", "-NoExit"}
+	}
+	if p, err := exec.LookPath("powershell.exe"); err == nil {
+		return p, []string{"-NoLogo", "-NoExit"}
+	}
+	comspec := os.Getenv("COMSPEC")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+	return comspec, nil
+}
+
+// execReplace runs argv to completion and propagates its exit code, since
+// Windows has no syscall.Exec equivalent to truly replace the process. It
+// only returns an error when the child could not be spawned at all.
+func execReplace(argv []string, env []string) error {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err == nil {
+		os.Exit(0)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
+
+func copyToClipboard(text string) error {
+	cmd := exec.Command("clip.exe")
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
@@ -0,0 +1,81 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// buildLcdBinary compiles the CLI into a temp dir once and returns its path.
+func buildLcdBinary(t *testing.T) string {
+	t.Helper()
+	binPath := filepath.Join(t.TempDir(), "lcd")
+	cmd := exec.Command("go", "build", "-o", binPath, ".")
+	cmd.Dir = mustGetwd(t)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building lcd: %v\n%s", err, out)
+	}
+	return binPath
+}
+
+func mustGetwd(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd: %v", err)
+	}
+	return wd
+}
+
+// TestEnterDirectoryFromDeletedCwd simulates a shell sitting in a directory
+// that has since been removed (e.g. by `git worktree remove` in another
+// pane) and confirms `lcd` still resolves and enters the target instead of
+// failing on the now-ENOENT os.Getwd() call.
+func TestEnterDirectoryFromDeletedCwd(t *testing.T) {
+	binPath := buildLcdBinary(t)
+
+	home := t.TempDir()
+	target := filepath.Join(home, "projects", "widgets")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("MkdirAll(target): %v", err)
+	}
+
+	dbPath := filepath.Join(home, dbFilename)
+	dbContents := home + "\n" + target + "\n"
+	if err := os.WriteFile(dbPath, []byte(dbContents), 0o644); err != nil {
+		t.Fatalf("WriteFile(db): %v", err)
+	}
+
+	// Put the *test process* in a directory, then rm -rf it out from under
+	// itself: on Unix the process keeps the open fd for its cwd, so
+	// os.Getwd() starts failing with ENOENT even though the process is still
+	// happily running there - exactly what happens to a shell whose
+	// directory was removed in another pane.
+	deletedCwd := t.TempDir()
+	origCwd := mustGetwd(t)
+	if err := os.Chdir(deletedCwd); err != nil {
+		t.Fatalf("Chdir(deletedCwd): %v", err)
+	}
+	defer os.Chdir(origCwd)
+	if err := os.RemoveAll(deletedCwd); err != nil {
+		t.Fatalf("RemoveAll(deletedCwd): %v", err)
+	}
+
+	cmd := exec.Command(binPath, "widgets")
+	cmd.Env = append(os.Environ(), "HOME="+home, "SHELL=/bin/true")
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("lcd exited with error: %v\noutput:\n%s", err, out.String())
+	}
+
+	if want := "cd " + target; !bytes.Contains(out.Bytes(), []byte(want)) {
+		t.Fatalf("expected output to contain %q, got:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const frecencyFilename = ".lcd-frecency.txt"
+
+// frecencyEntry records how often, and how recently, a path has been
+// visited via a direct `lcd <term>` cd.
+type frecencyEntry struct {
+	frequency  int
+	lastAccess int64 // unix seconds
+}
+
+// loadFrecency reads the frecency file into a path -> entry map. A missing
+// file just means there's no history yet, not an error.
+func loadFrecency(path string) (map[string]*frecencyEntry, error) {
+	entries := make(map[string]*frecencyEntry)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		freq, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		last, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries[fields[0]] = &frecencyEntry{frequency: freq, lastAccess: last}
+	}
+	return entries, scanner.Err()
+}
+
+// saveFrecency writes entries back out atomically (temp file + rename) so a
+// crash, or two `lcd` invocations racing, never leaves a half-written file.
+func saveFrecency(path string, entries map[string]*frecencyEntry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	writer := bufio.NewWriter(tmp)
+	for p, e := range entries {
+		if _, err := fmt.Fprintf(writer, "%s\t%d\t%d\n", p, e.frequency, e.lastAccess); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// recordVisit increments the usage counter and refreshes the timestamp for
+// resolvedPath, so future searches can prefer directories actually used
+// over merely shortest-path ones.
+func recordVisit(frecencyPath string, resolvedPath string, now time.Time) error {
+	entries, err := loadFrecency(frecencyPath)
+	if err != nil {
+		return err
+	}
+	e, ok := entries[resolvedPath]
+	if !ok {
+		e = &frecencyEntry{}
+		entries[resolvedPath] = e
+	}
+	e.frequency++
+	e.lastAccess = now.Unix()
+	return saveFrecency(frecencyPath, entries)
+}
+
+// forgetFrecencyEntry removes a path's usage history, e.g. because it no
+// longer exists. It reports whether an entry was actually found.
+func forgetFrecencyEntry(frecencyPath string, target string) (bool, error) {
+	entries, err := loadFrecency(frecencyPath)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := entries[target]; !ok {
+		return false, nil
+	}
+	delete(entries, target)
+	return true, saveFrecency(frecencyPath, entries)
+}
+
+// frecencyDecay implements the z/autojump-style aging curve: very recent
+// visits count fully, older ones are discounted the longer ago they were.
+func frecencyDecay(age time.Duration) float64 {
+	switch {
+	case age <= time.Hour:
+		return 1.0
+	case age <= 24*time.Hour:
+		return 0.5
+	case age <= 7*24*time.Hour:
+		return 0.25
+	default:
+		return 0.1
+	}
+}
+
+// frecencyScore combines visit frequency with how recently a path was
+// visited. A nil entry (no history) scores zero.
+func frecencyScore(e *frecencyEntry, now time.Time) float64 {
+	if e == nil {
+		return 0
+	}
+	age := now.Sub(time.Unix(e.lastAccess, 0))
+	return float64(e.frequency) * frecencyDecay(age)
+}
+
+// rankCandidates sorts a copy of candidates by frecency score, best first,
+// breaking ties by shortest path (which also covers the common case of no
+// usage history at all, where every score is zero). tied reports whether
+// the top two candidates scored equally - the signal used to auto-enable
+// interactive selection on a terminal, since picking between them would
+// otherwise be arbitrary.
+func rankCandidates(candidates []string, frecencyPath string) (ranked []string, tied bool) {
+	ranked = append([]string(nil), candidates...)
+
+	entries, _ := loadFrecency(frecencyPath)
+	now := time.Now()
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si := frecencyScore(entries[ranked[i]], now)
+		sj := frecencyScore(entries[ranked[j]], now)
+		if si != sj {
+			return si > sj
+		}
+		return len(ranked[i]) < len(ranked[j])
+	})
+
+	if len(ranked) > 1 {
+		tied = frecencyScore(entries[ranked[0]], now) == frecencyScore(entries[ranked[1]], now)
+	}
+	return ranked, tied
+}
+
+// listMatches returns up to limit candidate directories matching term,
+// ranked by frecency score (ties broken by shortest path), for the --list
+// flag.
+func listMatches(dbPath string, frecencyPath string, term string, limit int) ([]string, error) {
+	exactMatches, bestPartial, err := scanDatabase(dbPath, term)
+	if err != nil {
+		return nil, err
+	}
+	candidates := exactMatches
+	if bestPartial != "" {
+		candidates = append(candidates, bestPartial)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("directory not found: %s", term)
+	}
+
+	ranked, _ := rankCandidates(candidates, frecencyPath)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
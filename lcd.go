@@ -4,9 +4,11 @@
 // https://github.com/Dorn8010/lcd
 
 // To compile on Linux :
-// sudo apt install golang && go build -o lcd lcd.go
+// sudo apt install golang && go build -o lcd .
 // To compile on Mac :
-// brew install go && go build -o lcd lcd.go
+// brew install go && go build -o lcd .
+// To compile on Windows :
+// go build -o lcd.exe .
 // To install locally
 // sudo cp lcd /usr/local/bin/
 
@@ -15,15 +17,16 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
-	"io/fs"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"syscall"
+	"time"
 )
 
 // Constants
@@ -34,19 +37,32 @@ const (
 
 // Config holds command line arguments
 type Config struct {
-	help       bool
-	version    bool
-	verbose    bool
-	printOnly  bool
-	copyToClip bool
-	rescan     bool
-	newBaseDir string
-	searchTerm string
+	help        bool
+	version     bool
+	verbose     bool
+	printOnly   bool
+	copyToClip  bool
+	rescan      bool
+	newBaseDir  string
+	searchTerm  string
+	jobs        int
+	skipDirs    string
+	maxDepth    int
+	list        bool
+	forgetPath  string
+	interactive bool
+	watch       bool
+	maxAge      time.Duration
+	autoRescan  bool
 }
 
+// frecencyListLimit caps how many ranked candidates --list prints.
+const frecencyListLimit = 10
+
 func main() {
 	// Parse Flags
 	cfg := parseFlags()
+	sh := NewShell(os.Stdout, os.Stderr, execReplace)
 
 	if cfg.help {
 		printHelp()
@@ -64,6 +80,20 @@ func main() {
 		fatal("Could not determine user home directory: %v", err)
 	}
 	dbPath := filepath.Join(homeDir, dbFilename)
+	frecencyPath := filepath.Join(homeDir, frecencyFilename)
+
+	if cfg.forgetPath != "" {
+		found, err := forgetFrecencyEntry(frecencyPath, cfg.forgetPath)
+		if err != nil {
+			fatal("Could not update frecency history: %v", err)
+		}
+		if found {
+			fmt.Printf("Forgot %s\n", cfg.forgetPath)
+		} else {
+			fmt.Printf("No frecency history for %s\n", cfg.forgetPath)
+		}
+		os.Exit(0)
+	}
 
 	// --- LOGIC FLOW ---
 
@@ -88,19 +118,50 @@ func main() {
 			if scanner.Scan() {
 				storedBase := strings.TrimSpace(scanner.Text())
 				if storedBase != "" {
-					baseDir = storedBase
+					// The database always stores forward-slash paths (see
+					// scanDatabase/baseName); convert back to the host's
+					// native separator before using it as a real filesystem
+					// path.
+					baseDir = filepath.FromSlash(storedBase)
 				}
 			}
 			f.Close()
 		}
 	}
 
+	// 2b. Watch mode never searches: it just keeps the database fresh until
+	// interrupted.
+	if cfg.watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		opts := ScanOptions{
+			Jobs:     cfg.jobs,
+			SkipDirs: strings.Split(cfg.skipDirs, ","),
+			MaxDepth: cfg.maxDepth,
+			Verbose:  cfg.verbose,
+		}
+		err := runWatch(ctx, dbPath, baseDir, opts)
+		stop()
+		if err != nil {
+			fatal("Error watching %s: %v", baseDir, err)
+		}
+		os.Exit(0)
+	}
+
 	// 3. Rescan if required
 	if cfg.rescan {
 		if cfg.verbose {
 			fmt.Fprintf(os.Stderr, "Scanning directories starting from: %s\n", baseDir)
 		}
-		err := generateDatabase(dbPath, baseDir)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		opts := ScanOptions{
+			Jobs:     cfg.jobs,
+			SkipDirs: strings.Split(cfg.skipDirs, ","),
+			MaxDepth: cfg.maxDepth,
+			Verbose:  cfg.verbose,
+		}
+		err := generateDatabase(ctx, dbPath, baseDir, opts)
+		stop()
 		if err != nil {
 			fatal("Error generating database: %v", err)
 		}
@@ -112,6 +173,11 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Database updated.")
 			os.Exit(1)
 		}
+	} else {
+		// The database wasn't just (re)written above, so it may have drifted
+		// from the real filesystem. Warn, or with --auto-rescan, refresh it in
+		// the background while still serving this query from the stale index.
+		checkDatabaseAge(dbPath, baseDir, cfg)
 	}
 
 	// 4. Perform Search
@@ -119,72 +185,109 @@ func main() {
 		fatal("Please provide a directory name to search for.")
 	}
 
-	match, err := searchDatabaseOptimized(dbPath, cfg.searchTerm)
+	if cfg.list {
+		matches, err := listMatches(dbPath, frecencyPath, cfg.searchTerm, frecencyListLimit)
+		if err != nil {
+			fatal("%v", err)
+		}
+		for _, m := range matches {
+			fmt.Println(filepath.FromSlash(m))
+		}
+		os.Exit(0)
+	}
+
+	exactMatches, bestPartial, err := scanDatabase(dbPath, cfg.searchTerm)
 	if err != nil {
 		fatal("%v", err)
 	}
+	candidates := exactMatches
+	if len(candidates) == 0 {
+		if bestPartial == "" {
+			fatal("directory not found: %s", cfg.searchTerm)
+		}
+		candidates = []string{bestPartial}
+	}
 
-	// 5. Handle "Print" or "Copy" Actions (These exit early)
-	if cfg.printOnly {
-		fmt.Println(match)
+	// An ambiguous exact match under --print/--copy just dumps every
+	// candidate, one per line, for shell-side filtering (e.g. `| fzf`):
+	// there's no terminal left to prompt on once output is being piped.
+	if len(candidates) > 1 && (cfg.printOnly || cfg.copyToClip) {
+		for _, c := range candidates {
+			sh.ShowCmd("%s\n", filepath.FromSlash(c))
+		}
 		os.Exit(0)
 	}
 
-	if cfg.copyToClip {
-		err := copyToClipboard(match)
+	ranked, tied := rankCandidates(candidates, frecencyPath)
+	match := ranked[0]
+
+	// The interactive selector only applies to the direct-cd path: once
+	// syscall.Exec replaces this process there's no shell left to prompt
+	// on, so disambiguation has to happen first. Auto-enable it when
+	// stdout is a terminal and the top candidates were a genuine tie,
+	// rather than silently guessing.
+	if len(ranked) > 1 && (cfg.interactive || (tied && isTerminal(os.Stdout))) {
+		choice, err := selectCandidate(ranked)
 		if err != nil {
-			fatal("Failed to copy to clipboard: %v", err)
+			fatal("Interactive selection failed: %v", err)
 		}
-		fmt.Printf("Copied to clipboard: %s\n", match)
-		os.Exit(0)
+		match = choice
 	}
 
-	// 6. DIRECT CHANGE DIRECTORY (Method 1)
-	// Instead of printing, we replace the process.
-	enterDirectory(match)
+	// 5. Handle "Print", "Copy", or the default direct-cd action.
+	performAction(cfg, sh, match, frecencyPath)
+	os.Exit(0)
 }
 
-// --- CORE FUNCTION FOR METHOD 1 ---
+// checkDatabaseAge warns when dbPath is older than cfg.maxAge, since a full
+// rescan is expensive enough that users forget to run --rescan and the
+// database silently drifts from the real filesystem. With --auto-rescan it
+// refreshes the database in the background instead of just warning, while
+// still letting the current query run against the stale index.
+func checkDatabaseAge(dbPath string, baseDir string, cfg Config) {
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return
+	}
 
-func enterDirectory(targetPath string) {
+	age := time.Since(info.ModTime())
+	if age <= cfg.maxAge {
+		return
+	}
 
-	// A. Change the Go process's working directory
-        currentDir, err := os.Getwd()
-        if err == nil {
-		// Clean both paths to resolve trailing slashes or relative components
-		if filepath.Clean(currentDir) == filepath.Clean(targetPath) {
-			fmt.Printf("Already in: %s\n", targetPath)
-			os.Exit(0) // Stop here, do not spawn a new shell
+	if cfg.autoRescan {
+		if err := autoRescanInBackground(baseDir); err != nil && cfg.verbose {
+			fmt.Fprintf(os.Stderr, "Could not start background rescan: %v\n", err)
 		}
+		return
 	}
-        
-        err = os.Chdir(targetPath)
+
+	fmt.Fprintf(os.Stderr, "Note: directory index is %s old; run --rescan to refresh (or pass --auto-rescan to do this automatically).\n", age.Round(time.Second))
+}
+
+// autoRescanInBackground re-invokes this same binary with --rescan against
+// baseDir and detaches it, rather than spawning a goroutine: the current
+// process is either about to syscall.Exec (replacing itself) or os.Exit, and
+// neither leaves a goroutine alive to finish the scan.
+func autoRescanInBackground(baseDir string) error {
+	self, err := os.Executable()
 	if err != nil {
-		fatal("Could not enter directory %s: %v", targetPath, err)
+		self = os.Args[0]
 	}
+	cmd := exec.Command(self, "--newbasedir", baseDir, "--rescan")
+	cmd.Stdin = nil
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	return cmd.Start()
+}
 
-	// B. Detect the user's current shell
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/sh"
-	}
-      
-	// C. Prepare Arguments
-	// argv[0] is the command name (by convention)
-	// "-i" forces the shell to be interactive (load history/rc files)
-	// Note: Some shells behave better if argv[0] starts with "-" (login shell),
-	// but "-i" is the standard way to just "start a new interactive session".
-	args := []string{shell, "-i"}
-
-
-	fmt.Printf("cd %s\n", targetPath)
-	// D. Execute
-	env := os.Environ()
-	err = syscall.Exec(shell, args, env)
-	
+// isTerminal reports whether f is connected to an interactive terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
 	if err != nil {
-		fatal("Failed to spawn new shell: %v", err)
+		return false
 	}
+	return fi.Mode()&os.ModeCharDevice != 0
 }
 
 // --- HELPER FUNCTIONS ---
@@ -200,6 +303,16 @@ func parseFlags() Config {
 	flag.BoolVar(&cfg.copyToClip, "copy", false, "Copy path to clipboard")
 	flag.BoolVar(&cfg.rescan, "rescan", false, "Force a rescan")
 	flag.StringVar(&cfg.newBaseDir, "newbasedir", "", "Set a new root directory")
+	flag.IntVar(&cfg.jobs, "jobs", runtime.NumCPU(), "Number of concurrent workers used to scan the filesystem")
+	flag.StringVar(&cfg.skipDirs, "skip", ".git", "Comma-separated directory names to prune while scanning")
+	flag.IntVar(&cfg.maxDepth, "max-depth", 0, "Maximum directory depth to scan below the base directory (0 = unlimited)")
+	flag.BoolVar(&cfg.list, "list", false, "List the top ranked matches for the search term instead of acting on one")
+	flag.StringVar(&cfg.forgetPath, "forget", "", "Remove a path from the frecency history")
+	flag.BoolVar(&cfg.interactive, "interactive", false, "Prompt to pick among ambiguous matches")
+	flag.BoolVar(&cfg.interactive, "i", false, "Prompt to pick among ambiguous matches")
+	flag.BoolVar(&cfg.watch, "watch", false, "Run as a daemon that keeps the database up to date as directories change")
+	flag.DurationVar(&cfg.maxAge, "max-age", 24*time.Hour, "Warn when the database is older than this")
+	flag.BoolVar(&cfg.autoRescan, "auto-rescan", false, "When the database is older than --max-age, kick off a background rescan instead of just warning")
 	flag.Parse()
 
 	args := flag.Args()
@@ -209,52 +322,23 @@ func parseFlags() Config {
 	return cfg
 }
 
-func generateDatabase(dbPath string, baseDir string) error {
-	file, err := os.Create(dbPath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	_, err = writer.WriteString(baseDir + "\n")
-	if err != nil {
-		return err
-	}
-        fmt.Printf("(Re-)Scanning directory tree from %s\n", baseDir)
-	err = filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			// Permission denied or other error, skip directory
-			return filepath.SkipDir
-		}
-
-		// CHANGE: We now allow hidden directories (starting with ".")
-		// We only skip ".git" specifically because it contains thousands of 
-		// internal files that are useless for navigation and slow down the search.
-		if d.IsDir() && d.Name() == ".git" {
-			return filepath.SkipDir
-		}
-
-		if d.IsDir() {
-			_, err := writer.WriteString(path + "\n")
-			if err != nil {
-				return err
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return err
+// baseName returns the final path component, without relying on
+// filepath.Base so it behaves the same regardless of the host OS (the
+// database always stores forward-slash paths).
+func baseName(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		return path[i+1:]
 	}
-	return writer.Flush()
+	return path
 }
 
-
-func searchDatabaseOptimized(dbPath string, term string) (string, error) {
+// scanDatabase reads dbPath and returns every exact (case-insensitive) name
+// match plus the shortest partial match, the split main() has always used
+// to prioritize exact matches.
+func scanDatabase(dbPath string, term string) (exactMatches []string, bestPartial string, err error) {
 	file, err := os.Open(dbPath)
 	if err != nil {
-		return "", fmt.Errorf("could not open database: %v", err)
+		return nil, "", fmt.Errorf("could not open database: %v", err)
 	}
 	defer file.Close()
 
@@ -262,69 +346,24 @@ func searchDatabaseOptimized(dbPath string, term string) (string, error) {
 	scanner.Scan() // Skip header
 
 	termLower := strings.ToLower(term)
-	
-	var bestExact string
-	var bestPartial string
-	
-	bestExactLen := int(^uint(0) >> 1)
 	bestPartialLen := int(^uint(0) >> 1)
 
 	for scanner.Scan() {
 		path := scanner.Text()
-		
-		lastSlash := strings.LastIndexByte(path, '/')
-		name := path
-		if lastSlash >= 0 {
-			name = path[lastSlash+1:]
-		}
-		
-		nameLower := strings.ToLower(name)
-		pathLen := len(path)
+		nameLower := strings.ToLower(baseName(path))
 
 		if nameLower == termLower {
-			if pathLen < bestExactLen {
-				bestExact = path
-				bestExactLen = pathLen
-			}
+			exactMatches = append(exactMatches, path)
 			continue
 		}
 
-		if strings.Contains(nameLower, termLower) {
-			if pathLen < bestPartialLen {
-				bestPartial = path
-				bestPartialLen = pathLen
-			}
+		if strings.Contains(nameLower, termLower) && len(path) < bestPartialLen {
+			bestPartial = path
+			bestPartialLen = len(path)
 		}
 	}
 
-	if bestExact != "" {
-		return bestExact, nil
-	}
-	if bestPartial != "" {
-		return bestPartial, nil
-	}
-
-	return "", fmt.Errorf("directory not found: %s", term)
-}
-
-func copyToClipboard(text string) error {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("pbcopy")
-	case "linux":
-		if _, err := exec.LookPath("xclip"); err == nil {
-			cmd = exec.Command("xclip", "-selection", "clipboard")
-		} else if _, err := exec.LookPath("wl-copy"); err == nil {
-			cmd = exec.Command("wl-copy")
-		} else {
-			return fmt.Errorf("no clipboard tool found")
-		}
-	default:
-		return fmt.Errorf("unsupported OS")
-	}
-	cmd.Stdin = strings.NewReader(text)
-	return cmd.Run()
+	return exactMatches, bestPartial, scanner.Err()
 }
 
 func printHelp() {
@@ -343,12 +382,24 @@ Options:
   --copy             Copy the found path to system clipboard
   --rescan           Force a rescan of the filesystem
   --newbasedir <dir> Set a new root directory for scanning (implies --rescan)
+  --jobs <n>         Number of concurrent workers used to scan (default: NumCPU)
+  --skip <list>      Comma-separated directory names to prune, e.g. .git,node_modules
+  --max-depth <n>    Maximum directory depth to scan below the base directory (0 = unlimited)
+  --list             List the top ranked matches for the search term instead of acting on one
+  --forget <path>    Remove a path from the frecency history
+  --interactive, -i  Prompt to pick among ambiguous matches (uses fzf if on PATH)
+  --watch            Run as a daemon, keeping the database up to date as directories change
+  --max-age <dur>    Warn when the database is older than this (default: 24h)
+  --auto-rescan      When the database is stale, refresh it in the background instead of warning
   --version          Show version info
   --help, -h         Show this help message
 
 Search Logic:
   1. Searches for an Exact Match (case-insensitive) of the directory name.
   2. If not found, searches for a Partial Match.
+  3. Among multiple exact matches, the one visited most often and most
+     recently (its "frecency") wins; falls back to the shortest path when
+     there is no usage history yet. See ~/.lcd-frecency.txt.
 `, version)
 }
 
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseNameWindowsStylePath(t *testing.T) {
+	// The database always stores forward-slash paths (scanner.go normalizes
+	// with filepath.ToSlash at write time), including ones originally
+	// produced on Windows, e.g. C:\Users\alex\projects\widgets. baseName
+	// must extract the leaf name from that canonical form regardless of
+	// which OS lcd itself is running on.
+	got := baseName("C:/Users/alex/projects/widgets")
+	if want := "widgets"; got != want {
+		t.Errorf("baseName(%q) = %q, want %q", "C:/Users/alex/projects/widgets", got, want)
+	}
+}
+
+func TestScanDatabaseWindowsStylePaths(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), dbFilename)
+	contents := "C:/Users/alex\n" +
+		"C:/Users/alex/projects\n" +
+		"C:/Users/alex/projects/widgets\n" +
+		"C:/Users/alex/projects/widgets-archive\n"
+	if err := os.WriteFile(dbPath, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	exact, partial, err := scanDatabase(dbPath, "widgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"C:/Users/alex/projects/widgets"}; len(exact) != 1 || exact[0] != want[0] {
+		t.Errorf("exactMatches = %v, want %v", exact, want)
+	}
+	_ = partial // scanDatabase also reports the shortest partial match; main only falls back to it when exact is empty, while --list ranks it alongside the exact matches
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanOptions controls how generateDatabase walks the filesystem.
+type ScanOptions struct {
+	Jobs     int      // number of concurrent worker goroutines (< 1 treated as 1)
+	SkipDirs []string // directory names pruned entirely, e.g. ".git"
+	MaxDepth int      // maximum depth below baseDir to descend, 0 = unlimited
+	Verbose  bool
+}
+
+// dirJob is one directory queued for a worker to list, along with its depth
+// relative to baseDir (baseDir itself is depth 0).
+type dirJob struct {
+	path  string
+	depth int
+}
+
+// dirJobQueue is an unbounded work queue for dirJobs. A plain buffered
+// channel deadlocks here: workers are both the producers (a directory with
+// many subdirectories enqueues one dirJob per child) and the consumers, so
+// with a full channel and every worker simultaneously blocked trying to
+// push more children onto it, nothing is left running to drain it -
+// guaranteed with --jobs 1 (the default on a single-core host) against any
+// directory with more entries than the channel's capacity. Backing the
+// queue with a growable slice instead means push never blocks.
+type dirJobQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []dirJob
+	closed bool
+}
+
+func newDirJobQueue() *dirJobQueue {
+	q := &dirJobQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push enqueues job. It never blocks.
+func (q *dirJobQueue) push(job dirJob) {
+	q.mu.Lock()
+	q.items = append(q.items, job)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// close signals that no more jobs will be pushed; pop returns ok=false once
+// the queue has been drained.
+func (q *dirJobQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// pop blocks until a job is available or the queue is closed and empty.
+func (q *dirJobQueue) pop() (dirJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return dirJob{}, false
+	}
+	job := q.items[0]
+	q.items = q.items[1:]
+	return job, true
+}
+
+// generateDatabase concurrently walks baseDir and writes every directory it
+// finds to dbPath, one per line, preceded by a header line holding baseDir
+// itself. A bounded pool of worker goroutines pulls directories off a shared
+// queue (BFS) and lists them with os.ReadDir, rather than the single
+// filepath.WalkDir call this replaced, so a slow mount or a huge tree no
+// longer serializes the whole scan behind one os.ReadDir at a time. It also
+// reacts to ctx cancellation (e.g. SIGINT) instead of always running to
+// completion.
+func generateDatabase(ctx context.Context, dbPath string, baseDir string, opts ScanOptions) error {
+	file, err := os.Create(dbPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if _, err := writer.WriteString(filepath.ToSlash(baseDir) + "\n"); err != nil {
+		return err
+	}
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "(Re-)Scanning directory tree from %s\n", baseDir)
+	}
+
+	start := time.Now()
+
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	skip := make(map[string]bool, len(opts.SkipDirs))
+	for _, name := range opts.SkipDirs {
+		if name = strings.TrimSpace(name); name != "" {
+			skip[name] = true
+		}
+	}
+
+	queue := newDirJobQueue()
+	found := make(chan string, jobs*4)
+
+	// The writer runs on its own goroutine so workers never block on disk
+	// I/O for the shared bufio.Writer, and so there's a single, race-free
+	// owner of it.
+	var writerWg sync.WaitGroup
+	var writeErr error
+	writerWg.Add(1)
+	go func() {
+		defer writerWg.Done()
+		for path := range found {
+			if _, err := writer.WriteString(path + "\n"); err != nil && writeErr == nil {
+				writeErr = err
+			}
+		}
+	}()
+
+	// pending counts directories that have been queued but not yet listed;
+	// once it reaches zero the whole tree has been discovered and the queue
+	// can be closed so each worker's pop returns ok=false and it exits.
+	var pending sync.WaitGroup
+	pending.Add(1)
+	found <- filepath.ToSlash(baseDir) // baseDir is itself a valid navigation target, like the old root visit
+	queue.push(dirJob{path: baseDir, depth: 0})
+
+	var workersWg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		workersWg.Add(1)
+		go func() {
+			defer workersWg.Done()
+			for {
+				job, ok := queue.pop()
+				if !ok {
+					return
+				}
+				scanDir(ctx, job, opts.MaxDepth, skip, queue, found, &pending)
+			}
+		}()
+	}
+
+	go func() {
+		pending.Wait()
+		queue.close()
+	}()
+
+	workersWg.Wait()
+	close(found)
+	writerWg.Wait()
+
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "Scan took %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// scanDir lists one directory, recovering from permission errors the same
+// way the old filepath.SkipDir handling did (silently skip), and enqueues
+// any subdirectories it finds, unless they're pruned by name or max-depth.
+func scanDir(ctx context.Context, job dirJob, maxDepth int, skip map[string]bool, queue *dirJobQueue, found chan<- string, pending *sync.WaitGroup) {
+	defer pending.Done()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	entries, err := os.ReadDir(job.path)
+	if err != nil {
+		// Permission denied or other error: skip this directory, same as
+		// the single-threaded walker's filepath.SkipDir.
+		return
+	}
+
+	atMaxDepth := maxDepth > 0 && job.depth+1 >= maxDepth
+
+	for _, entry := range entries {
+		if !entry.IsDir() || skip[entry.Name()] {
+			continue
+		}
+
+		childPath := filepath.Join(job.path, entry.Name())
+
+		select {
+		case found <- filepath.ToSlash(childPath):
+		case <-ctx.Done():
+			return
+		}
+
+		if atMaxDepth {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		pending.Add(1)
+		queue.push(dirJob{path: childPath, depth: job.depth + 1})
+	}
+}
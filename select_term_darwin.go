@@ -0,0 +1,12 @@
+//go:build darwin
+
+package main
+
+import "syscall"
+
+// ioctlGetTermios/ioctlSetTermios are the termios ioctl requests on BSD-derived
+// kernels (Darwin uses TIOCGETA/TIOCSETA rather than Linux's TCGETS/TCSETS).
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
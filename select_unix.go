@@ -0,0 +1,70 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// promptAndReadChoice lists candidates on /dev/tty and reads a single raw
+// keypress naming the chosen index (1-based) - no Enter required. /dev/tty
+// is opened directly (rather than using stdin/stdout) so the prompt still
+// works when those are redirected.
+func promptAndReadChoice(candidates []string) (int, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return 0, fmt.Errorf("could not open /dev/tty for interactive selection: %w", err)
+	}
+	defer tty.Close()
+
+	for i, c := range candidates {
+		fmt.Fprintf(tty, "%2d) %s\n", i+1, c)
+	}
+	fmt.Fprintf(tty, "Select [1-%d]: ", len(candidates))
+
+	fd := int(tty.Fd())
+	oldState, err := termMakeRaw(fd)
+	if err != nil {
+		return 0, fmt.Errorf("could not set /dev/tty to raw mode: %w", err)
+	}
+	defer termRestore(fd, oldState)
+
+	key := make([]byte, 1)
+	if _, err := tty.Read(key); err != nil {
+		return 0, err
+	}
+	fmt.Fprintln(tty)
+
+	n, err := strconv.Atoi(string(key))
+	if err != nil || n < 1 || n > len(candidates) {
+		return 0, fmt.Errorf("invalid selection %q", key)
+	}
+	return n - 1, nil
+}
+
+// termMakeRaw disables canonical mode and echo on fd so a single keypress
+// can be read without the user pressing Enter, returning the previous
+// termios state to restore afterwards.
+func termMakeRaw(fd int) (*syscall.Termios, error) {
+	old := &syscall.Termios{}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlGetTermios, uintptr(unsafe.Pointer(old))); errno != 0 {
+		return nil, errno
+	}
+
+	raw := *old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(&raw))); errno != 0 {
+		return nil, errno
+	}
+	return old, nil
+}
+
+func termRestore(fd int, state *syscall.Termios) {
+	syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(state)))
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// promptAndReadChoice lists candidates and reads a line naming the chosen
+// index. Windows consoles don't expose the same raw single-keypress
+// primitives as a Unix tty, so this falls back to a full line read from
+// stdin, Enter included.
+func promptAndReadChoice(candidates []string) (int, error) {
+	for i, c := range candidates {
+		fmt.Fprintf(os.Stderr, "%2d) %s\n", i+1, c)
+	}
+	fmt.Fprintf(os.Stderr, "Select [1-%d]: ", len(candidates))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(candidates) {
+		return 0, fmt.Errorf("invalid selection %q", line)
+	}
+	return n - 1, nil
+}
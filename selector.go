@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// selectCandidate disambiguates an ambiguous match. It prefers fzf, when
+// available on PATH, over the built-in numbered prompt, and otherwise reads
+// a single choice from the terminal. Because syscall.Exec is about to
+// replace this whole process, this interaction has to happen before
+// enterDirectory ever calls sh.Exec - there's no way to prompt afterwards.
+func selectCandidate(candidates []string) (string, error) {
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	// FZF_DEFAULT_COMMAND being set is a strong hint the user has fzf set up
+	// for exactly this kind of picker, but what actually matters is whether
+	// the binary itself is on PATH.
+	if os.Getenv("FZF_DEFAULT_COMMAND") != "" || fzfAvailable() {
+		if fzfPath, err := exec.LookPath("fzf"); err == nil {
+			return selectWithFzf(fzfPath, candidates)
+		}
+	}
+
+	idx, err := promptAndReadChoice(candidates)
+	if err != nil {
+		return "", err
+	}
+	return candidates[idx], nil
+}
+
+func fzfAvailable() bool {
+	_, err := exec.LookPath("fzf")
+	return err == nil
+}
+
+// selectWithFzf pipes candidates to fzf, one per line, and returns whatever
+// line the user picked from its stdout. fzf draws its own UI on /dev/tty,
+// so piping stdin and capturing stdout works without any extra plumbing.
+func selectWithFzf(fzfPath string, candidates []string) (string, error) {
+	cmd := exec.Command(fzfPath)
+	cmd.Stdin = strings.NewReader(strings.Join(candidates, "\n") + "\n")
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("fzf selection failed: %w", err)
+	}
+
+	choice := strings.TrimSpace(string(out))
+	if choice == "" {
+		return "", fmt.Errorf("no selection made")
+	}
+	return choice, nil
+}
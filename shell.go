@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Shell centralizes every side effect the CLI performs against the outside
+// world: writing status lines, changing directory, and handing control to an
+// interactive shell or the system clipboard. Routing those through one type
+// (rather than scattering fmt.Print*, os.Chdir and exec calls across main)
+// is what makes --print, --copy and the direct-cd path unit-testable: a test
+// builds a Shell whose exec/clipboard hooks just record their arguments
+// instead of spawning a real subprocess. Modeled on the Shell type in
+// cmd/go/internal/work/shell.go.
+type Shell struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	mu        sync.Mutex
+	printFunc func(w io.Writer, format string, args ...interface{})
+
+	cwd string // last directory we successfully Chdir'd into, if any
+
+	exec      func(argv []string, env []string) error
+	clipboard func(text string) error
+}
+
+// NewShell builds a Shell that writes status lines to stdout/stderr and
+// hands control to an interactive shell via exec when asked to enter a
+// directory.
+func NewShell(stdout, stderr io.Writer, exec func(argv, env []string) error) *Shell {
+	return &Shell{
+		stdout: stdout,
+		stderr: stderr,
+		printFunc: func(w io.Writer, format string, args ...interface{}) {
+			fmt.Fprintf(w, format, args...)
+		},
+		exec:      exec,
+		clipboard: copyToClipboard,
+	}
+}
+
+// ShowCmd prints a status line to stdout, serialized so concurrent callers
+// can't interleave output.
+func (s *Shell) ShowCmd(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.printFunc(s.stdout, format, args...)
+}
+
+// Errorf prints a status line to stderr under the same lock as ShowCmd.
+func (s *Shell) Errorf(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.printFunc(s.stderr, format, args...)
+}
+
+// Chdir changes the process's working directory and remembers it as the
+// Shell's current working directory hint.
+func (s *Shell) Chdir(dir string) error {
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	s.cwd = dir
+	return nil
+}
+
+// Exec hands control to argv[0], à la exec(3): on platforms that support
+// replacing the process image it never returns on success, and on others it
+// runs argv to completion and exits with its status. Either way it only
+// returns when the child could not be spawned at all.
+func (s *Shell) Exec(argv []string, env []string) error {
+	return s.exec(argv, env)
+}
+
+// CopyToClipboard copies text to the system clipboard.
+func (s *Shell) CopyToClipboard(text string) error {
+	return s.clipboard(text)
+}
+
+// enterDirectory changes into targetPath and hands control to an
+// interactive shell via sh.Exec, short-circuiting if the process is already
+// there. A successful entry (including the already-there case) records a
+// frecency visit so future searches can prefer directories actually used.
+func enterDirectory(sh *Shell, targetPath string, frecencyPath string) {
+	// The database (and therefore targetPath) always stores forward-slash
+	// paths; convert to the host's native separator before touching the
+	// filesystem with it, but keep recordVisit keyed on targetPath itself so
+	// frecency lookups (which compare against the database's own candidate
+	// strings) stay consistent.
+	nativeTarget := filepath.FromSlash(targetPath)
+
+	// Compare against the process's current working directory, but only if
+	// we can actually get one. A shell can easily be sitting in a directory
+	// that no longer exists (e.g. after `git worktree remove` or `rm -rf` in
+	// another pane), or Getwd can fail for other transient reasons; either way
+	// that just means there's nothing to compare against. Mirroring how
+	// cmd/go tolerates a missing CWD, fall straight through to the Chdir
+	// below instead of aborting on any Getwd error.
+	if cwd, err := os.Getwd(); err == nil {
+		if filepath.Clean(cwd) == filepath.Clean(nativeTarget) {
+			_ = recordVisit(frecencyPath, targetPath, time.Now()) // best-effort: a frecency write failure shouldn't block an already-there no-op
+			sh.ShowCmd("Already in: %s\n", nativeTarget)
+			os.Exit(0)
+		}
+	}
+
+	if err := sh.Chdir(nativeTarget); err != nil {
+		fatal("Could not enter directory %s: %v", nativeTarget, err)
+	}
+	_ = recordVisit(frecencyPath, targetPath, time.Now()) // best-effort: a frecency write failure shouldn't block the cd
+
+	argv, env := shellCommandArgv(nativeTarget)
+	sh.ShowCmd("cd %s\n", nativeTarget)
+	if err := sh.Exec(argv, env); err != nil {
+		fatal("Failed to spawn new shell: %v", err)
+	}
+}
+
+// performAction carries out whichever of --print, --copy, or the default
+// direct-cd behavior the user asked for, against match.
+func performAction(cfg Config, sh *Shell, match string, frecencyPath string) {
+	// match is stored forward-slash-canonical (see scanDatabase); convert to
+	// the host's native separator for anything shown to, or copied for, the
+	// user.
+	nativeMatch := filepath.FromSlash(match)
+
+	if cfg.printOnly {
+		sh.ShowCmd("%s\n", nativeMatch)
+		return
+	}
+
+	if cfg.copyToClip {
+		if err := sh.CopyToClipboard(nativeMatch); err != nil {
+			fatal("Failed to copy to clipboard: %v", err)
+		}
+		sh.ShowCmd("Copied to clipboard: %s\n", nativeMatch)
+		return
+	}
+
+	// DIRECT CHANGE DIRECTORY (Method 1): instead of printing, replace the
+	// process.
+	enterDirectory(sh, match, frecencyPath)
+}
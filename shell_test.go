@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeShell builds a Shell whose exec/clipboard hooks record their calls
+// instead of touching a real subprocess or the system clipboard, and whose
+// stdout/stderr are captured buffers.
+func fakeShell() (sh *Shell, stdout, stderr *bytes.Buffer, execCalls *[][]string, clipCalls *[]string) {
+	stdout = &bytes.Buffer{}
+	stderr = &bytes.Buffer{}
+	execCalls = &[][]string{}
+	clipCalls = &[]string{}
+
+	sh = NewShell(stdout, stderr, func(argv, env []string) error {
+		*execCalls = append(*execCalls, argv)
+		return nil
+	})
+	sh.clipboard = func(text string) error {
+		*clipCalls = append(*clipCalls, text)
+		return nil
+	}
+	return sh, stdout, stderr, execCalls, clipCalls
+}
+
+func TestPerformActionPrint(t *testing.T) {
+	sh, stdout, _, execCalls, clipCalls := fakeShell()
+
+	performAction(Config{printOnly: true}, sh, "/home/user/projects/widgets", filepath.Join(t.TempDir(), frecencyFilename))
+
+	if got, want := stdout.String(), "/home/user/projects/widgets\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	if len(*execCalls) != 0 || len(*clipCalls) != 0 {
+		t.Errorf("--print should not exec or touch the clipboard, got exec=%v clip=%v", *execCalls, *clipCalls)
+	}
+}
+
+func TestPerformActionCopy(t *testing.T) {
+	sh, stdout, _, execCalls, clipCalls := fakeShell()
+
+	performAction(Config{copyToClip: true}, sh, "/home/user/projects/widgets", filepath.Join(t.TempDir(), frecencyFilename))
+
+	if want := []string{"/home/user/projects/widgets"}; !reflect.DeepEqual(*clipCalls, want) {
+		t.Errorf("clipboard calls = %v, want %v", *clipCalls, want)
+	}
+	if got, want := stdout.String(), "Copied to clipboard: /home/user/projects/widgets\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	if len(*execCalls) != 0 {
+		t.Errorf("--copy should not exec, got %v", *execCalls)
+	}
+}
+
+func TestPerformActionDirectCd(t *testing.T) {
+	sh, stdout, _, execCalls, clipCalls := fakeShell()
+
+	target := filepath.Join(t.TempDir(), "widgets")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	origCwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	defer os.Chdir(origCwd)
+
+	performAction(Config{}, sh, target, filepath.Join(t.TempDir(), frecencyFilename))
+
+	if len(*execCalls) != 1 {
+		t.Fatalf("expected exactly one exec call, got %v", *execCalls)
+	}
+	if len(*clipCalls) != 0 {
+		t.Errorf("direct-cd should not touch the clipboard, got %v", *clipCalls)
+	}
+
+	wantPrefix := "cd " + target
+	if got := stdout.String(); got != wantPrefix+"\n" {
+		t.Errorf("stdout = %q, want %q", got, wantPrefix+"\n")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd after performAction: %v", err)
+	}
+	if filepath.Clean(cwd) != filepath.Clean(target) {
+		t.Errorf("process cwd = %q, want %q", cwd, target)
+	}
+}
+
+func TestShellExecPropagatesError(t *testing.T) {
+	boom := errors.New("boom")
+	sh := NewShell(&bytes.Buffer{}, &bytes.Buffer{}, func(argv, env []string) error {
+		return boom
+	})
+	if err := sh.Exec([]string{"/bin/sh"}, nil); !errors.Is(err, boom) {
+		t.Errorf("Exec error = %v, want %v", err, boom)
+	}
+}
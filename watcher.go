@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// runWatch is the --watch daemon loop. It walks baseDir once to build the
+// initial directory set, subscribing an fsnotify watch on every directory it
+// descends into along the way, then reacts to Create/Remove/Rename events as
+// they arrive instead of re-walking the tree: a Create adds the new
+// subtree (and its own watches) to the set, a Remove or Rename drops the
+// old one. dbPath is flushed (atomically, temp+rename) whenever the set
+// actually changes. It runs until ctx is canceled, e.g. by SIGINT.
+func runWatch(ctx context.Context, dbPath string, baseDir string, opts ScanOptions) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	skip := make(map[string]bool, len(opts.SkipDirs))
+	for _, name := range opts.SkipDirs {
+		if name = strings.TrimSpace(name); name != "" {
+			skip[name] = true
+		}
+	}
+
+	dirs := make(map[string]bool)
+	addSubtree(watcher, dirs, baseDir, baseDir, opts.MaxDepth, skip, false) // initial walk, not a change event: stay quiet even with --verbose
+	if err := flushTree(dbPath, baseDir, sortedKeys(dirs)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Watching %s for changes (%d directories, fsnotify). Press Ctrl-C to stop.\n", baseDir, len(dirs))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !handleEvent(watcher, dirs, baseDir, event, opts, skip) {
+				continue
+			}
+			if err := flushTree(dbPath, baseDir, sortedKeys(dirs)); err != nil {
+				return err
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
+		}
+	}
+}
+
+// handleEvent applies one fsnotify event to dirs and to watcher's
+// subscriptions, reporting whether the tracked directory set actually
+// changed (so runWatch can skip flushing dbPath on no-op events such as
+// Write/Chmod, or a Create for a plain file).
+func handleEvent(w *fsnotify.Watcher, dirs map[string]bool, baseDir string, event fsnotify.Event, opts ScanOptions, skip map[string]bool) bool {
+	switch {
+	case event.Op&fsnotify.Create != 0:
+		info, err := os.Stat(event.Name)
+		if err != nil || !info.IsDir() || skip[filepath.Base(event.Name)] {
+			return false
+		}
+		addSubtree(w, dirs, baseDir, event.Name, opts.MaxDepth, skip, opts.Verbose)
+		return true
+
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		return removeSubtree(w, dirs, event.Name, opts.Verbose)
+
+	default:
+		return false
+	}
+}
+
+// addSubtree records path, and anything already nested inside it, as newly
+// discovered, installing an fsnotify watch on every directory whose future
+// children we still want to learn about. It's the incremental counterpart
+// to scanner.go's scanDir: scanDir walks the whole tree up front, this
+// walks just the subtree that appeared under a single Create event (e.g.
+// mkdir -p, or an existing directory tree getting moved into baseDir).
+func addSubtree(w *fsnotify.Watcher, dirs map[string]bool, baseDir, path string, maxDepth int, skip map[string]bool, verbose bool) {
+	dirs[filepath.ToSlash(path)] = true
+	if verbose {
+		fmt.Fprintf(os.Stderr, "+ %s\n", filepath.ToSlash(path))
+	}
+
+	if maxDepth > 0 && dirDepth(baseDir, path) >= maxDepth {
+		return // leaf: tracked, same as scanDir, but not watched or descended into
+	}
+
+	if err := w.Add(path); err != nil {
+		return // permission denied or similar: skip silently, like scanDir's tolerant os.ReadDir failure
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || skip[entry.Name()] {
+			continue
+		}
+		addSubtree(w, dirs, baseDir, filepath.Join(path, entry.Name()), maxDepth, skip, verbose)
+	}
+}
+
+// removeSubtree drops path, and any directory nested under it, from dirs -
+// mirroring what a deleted or renamed-away directory takes with it - and
+// stops watching each one. It reports whether anything was actually
+// tracked under path.
+func removeSubtree(w *fsnotify.Watcher, dirs map[string]bool, path string, verbose bool) bool {
+	canonical := filepath.ToSlash(path)
+	prefix := canonical + "/"
+
+	changed := false
+	for d := range dirs {
+		if d != canonical && !strings.HasPrefix(d, prefix) {
+			continue
+		}
+		delete(dirs, d)
+		w.Remove(filepath.FromSlash(d)) // best-effort: it's already gone from disk, this just drops bookkeeping
+		if verbose {
+			fmt.Fprintf(os.Stderr, "- %s\n", d)
+		}
+		changed = true
+	}
+	return changed
+}
+
+// dirDepth reports path's depth below baseDir (baseDir itself is depth 0),
+// matching how scanner.go's dirJob.depth is derived while walking.
+func dirDepth(baseDir, path string) int {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return len(strings.Split(filepath.ToSlash(rel), "/"))
+}
+
+// sortedKeys returns m's keys sorted, for building the dbPath contents
+// flushTree writes.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flushTree atomically (temp file + rename) writes baseDir's header line
+// followed by every entry in dirs, matching generateDatabase's file format.
+func flushTree(dbPath string, baseDir string, dirs []string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), filepath.Base(dbPath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	writer := bufio.NewWriter(tmp)
+	if _, err := writer.WriteString(filepath.ToSlash(baseDir) + "\n"); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	for _, d := range dirs {
+		if _, err := fmt.Fprintln(writer, d); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, dbPath)
+}